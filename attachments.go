@@ -0,0 +1,132 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+)
+
+// PutAttachment streams r as an attachment named name on the document
+// docID, using http.Request.Body directly so large payloads never need
+// to be buffered in memory. rev must be the document's current rev
+// (sent as If-Match); it returns the document's new rev on success. When
+// contentType is empty it is guessed from name's extension, falling back
+// to "application/octet-stream".
+func (db *DB) PutAttachment(docID, rev, name, contentType string, r io.Reader) (string, error) {
+	if contentType == "" {
+		contentType = mime.TypeByExtension(filepath.Ext(name))
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	url := fmt.Sprintf("%s/%s/%s/%s", db.Host, db.Database, docID, name)
+	req, err := http.NewRequest(http.MethodPut, url, r)
+	if err != nil {
+		return "", err
+	}
+	if err := db.authenticate(req); err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if rev != "" {
+		req.Header.Set("If-Match", rev)
+	}
+
+	resp, err := db.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return "", newCloudantError(resp.StatusCode, body)
+	}
+
+	var respBody struct {
+		Rev string `json:"rev"`
+	}
+	if err := json.Unmarshal(body, &respBody); err != nil {
+		return "", err
+	}
+
+	return respBody.Rev, nil
+}
+
+// GetAttachment streams the named attachment from docID. The caller must
+// Close the returned ReadCloser. The attachment's Content-Type is
+// returned alongside it.
+func (db *DB) GetAttachment(docID, name string) (io.ReadCloser, string, error) {
+	url := fmt.Sprintf("%s/%s/%s/%s", db.Host, db.Database, docID, name)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := db.authenticate(req); err != nil {
+		return nil, "", err
+	}
+
+	resp, err := db.client().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return nil, "", readErr
+		}
+		return nil, "", newCloudantError(resp.StatusCode, body)
+	}
+
+	return resp.Body, resp.Header.Get("Content-Type"), nil
+}
+
+// DeleteAttachment removes the named attachment from docID, which must
+// be at rev (sent as If-Match), and returns the document's new rev.
+func (db *DB) DeleteAttachment(docID, rev, name string) (string, error) {
+	url := fmt.Sprintf("%s/%s/%s/%s", db.Host, db.Database, docID, name)
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return "", err
+	}
+	if err := db.authenticate(req); err != nil {
+		return "", err
+	}
+	if rev != "" {
+		req.Header.Set("If-Match", rev)
+	}
+
+	resp, err := db.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return "", newCloudantError(resp.StatusCode, body)
+	}
+
+	var respBody struct {
+		Rev string `json:"rev"`
+	}
+	if err := json.Unmarshal(body, &respBody); err != nil {
+		return "", err
+	}
+
+	return respBody.Rev, nil
+}
@@ -0,0 +1,156 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BulkResult is the per-document outcome of a _bulk_docs call. Error and
+// Reason are only populated when the document failed (e.g. "conflict").
+type BulkResult struct {
+	ID     string `json:"id"`
+	Rev    string `json:"rev,omitempty"`
+	Error  string `json:"error,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Ok reports whether this document was written successfully.
+func (r BulkResult) Ok() bool {
+	return r.Error == ""
+}
+
+// BulkInsert writes docs to the database in a single _bulk_docs request
+// and returns the per-document results so callers can retry only the
+// subset that failed (typically "conflict").
+func (db *DB) BulkInsert(docs []interface{}) ([]BulkResult, error) {
+	return db.bulkDocs(docs, false)
+}
+
+// BulkUpdate is BulkInsert for documents that already carry an `_id` and
+// `_rev`.
+func (db *DB) BulkUpdate(docs []interface{}) ([]BulkResult, error) {
+	return db.bulkDocs(docs, false)
+}
+
+// bulkDeleteDoc is the shape _bulk_docs needs to delete an existing
+// document: its id, its current rev, and _deleted set.
+type bulkDeleteDoc struct {
+	ID      string `json:"_id"`
+	Rev     string `json:"_rev"`
+	Deleted bool   `json:"_deleted"`
+}
+
+// BulkDelete deletes the given id/rev pairs in a single _bulk_docs
+// request.
+func (db *DB) BulkDelete(docs map[string]string) ([]BulkResult, error) {
+	deletes := make([]interface{}, 0, len(docs))
+	for id, rev := range docs {
+		deletes = append(deletes, bulkDeleteDoc{ID: id, Rev: rev, Deleted: true})
+	}
+	return db.bulkDocs(deletes, false)
+}
+
+func (db *DB) bulkDocs(docs []interface{}, newEditsFalse bool) ([]BulkResult, error) {
+	url := fmt.Sprintf("%s/%s/_bulk_docs", db.Host, db.Database)
+
+	body := map[string]interface{}{"docs": docs}
+	if newEditsFalse {
+		body["new_edits"] = false
+	}
+
+	status, respBody, err := db.do(http.MethodPost, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	if status/100 != 2 {
+		return nil, newCloudantError(status, respBody)
+	}
+
+	var results []BulkResult
+	if err := json.Unmarshal(respBody, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// AllDocsOptions controls an _all_docs request.
+type AllDocsOptions struct {
+	Keys        []string
+	StartKey    string
+	EndKey      string
+	IncludeDocs bool
+	Limit       int
+	Skip        int
+}
+
+// AllDocsRow is a single row of an _all_docs response.
+type AllDocsRow struct {
+	ID    string `json:"id"`
+	Key   string `json:"key"`
+	Value struct {
+		Rev string `json:"rev"`
+	} `json:"value"`
+	Doc json.RawMessage `json:"doc,omitempty"`
+}
+
+// AllDocsResult is the decoded response of an _all_docs request.
+type AllDocsResult struct {
+	TotalRows int          `json:"total_rows"`
+	Offset    int          `json:"offset"`
+	Rows      []AllDocsRow `json:"rows"`
+}
+
+// AllDocs lists documents via _all_docs, optionally scoped by keys or a
+// startkey/endkey range.
+func (db *DB) AllDocs(opts AllDocsOptions) (AllDocsResult, error) {
+	var result AllDocsResult
+
+	q := map[string]interface{}{
+		"include_docs": opts.IncludeDocs,
+		"startkey":     jsonQueryValue(opts.StartKey),
+		"endkey":       jsonQueryValue(opts.EndKey),
+	}
+	if opts.Limit > 0 {
+		q["limit"] = opts.Limit
+	}
+	if opts.Skip > 0 {
+		q["skip"] = opts.Skip
+	}
+
+	url := fmt.Sprintf("%s/%s/_all_docs?%s", db.Host, db.Database, mapToQueryString(q))
+
+	var status int
+	var body []byte
+	var err error
+	if len(opts.Keys) > 0 {
+		status, body, err = db.do(http.MethodPost, url, map[string]interface{}{"keys": opts.Keys})
+	} else {
+		status, body, err = db.do(http.MethodGet, url, nil)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	if status/100 != 2 {
+		return result, newCloudantError(status, body)
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// jsonQueryValue quotes s as a JSON string for use as a startkey/endkey
+// query parameter, or returns "" so mapToQueryString omits it when empty.
+func jsonQueryValue(s string) string {
+	if s == "" {
+		return ""
+	}
+	encoded, _ := json.Marshal(s)
+	return string(encoded)
+}
@@ -0,0 +1,247 @@
+package cloudant
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// streamingHTTPClient is the shared *http.Client that DB.client returns
+// for a DB built with Setup. Every request of such a DB goes through it:
+// the buffered calls made by do() as well as the _changes feed and
+// attachment upload/download, which read or write their bodies
+// incrementally rather than holding the whole thing in memory.
+var streamingHTTPClient = &http.Client{}
+
+// errFeedClosed is returned internally by streamChanges when the server
+// closed the connection (feed=continuous) or the longpoll request simply
+// completed; Changes uses it to decide whether to reconnect.
+var errFeedClosed = errors.New("cloudant: changes feed closed")
+
+// handlerStopError wraps the error a ChangesHandler returned so Changes can
+// tell "handler asked to stop" apart from a transient stream error while
+// still handing the caller back their original error via errors.As/Is.
+type handlerStopError struct {
+	err error
+}
+
+func (e *handlerStopError) Error() string { return e.err.Error() }
+func (e *handlerStopError) Unwrap() error { return e.err }
+
+// ChangesOptions controls how DB.Changes reads the _changes endpoint.
+type ChangesOptions struct {
+	// Since is the update sequence to resume from. Use "now" to skip
+	// all existing history and only receive changes going forward.
+	Since string
+	// Feed selects the _changes feed mode: "continuous" (default) or
+	// "longpoll".
+	Feed string
+	// Heartbeat is the interval, in milliseconds, at which CouchDB sends
+	// a newline to keep a continuous connection alive.
+	Heartbeat int
+	// IncludeDocs requests the full document body alongside each change.
+	IncludeDocs bool
+	// Filter names a design-doc filter function, e.g. "myddoc/myfilter".
+	// Ignored when Selector is set.
+	Filter string
+	// Selector, when set, is POSTed as a Cloudant Query selector and the
+	// feed is filtered with filter=_selector.
+	Selector interface{}
+	// Backoff returns how long to wait before the given reconnect
+	// attempt (0-indexed). Defaults to a linear backoff of 500ms per
+	// attempt, capped at 30s.
+	Backoff func(attempt int) time.Duration
+}
+
+// ChangeRev is a single revision listed against a _changes entry.
+type ChangeRev struct {
+	Rev string `json:"rev"`
+}
+
+// Change is a single entry from a _changes feed.
+type Change struct {
+	Seq     string          `json:"seq"`
+	ID      string          `json:"id"`
+	Deleted bool            `json:"deleted"`
+	Changes []ChangeRev     `json:"changes"`
+	Doc     json.RawMessage `json:"doc,omitempty"`
+}
+
+// ChangesHandler is called once per change delivered from the feed.
+// Returning an error stops Changes and the error is returned to the
+// caller.
+type ChangesHandler func(Change) error
+
+// Changes opens the database's _changes feed and delivers each change to
+// handler until ctx is cancelled or handler returns an error. If the
+// underlying connection drops, Changes reconnects automatically using
+// opts.Backoff, resuming from the seq of the last change it delivered.
+// With Feed "longpoll" it returns nil once a single request completes.
+// It also returns without reconnecting on a non-2xx response other than
+// 429/503, and on a change line longer than 1 MiB.
+func (db *DB) Changes(ctx context.Context, opts ChangesOptions, handler ChangesHandler) error {
+	since := opts.Since
+	backoff := opts.Backoff
+	if backoff == nil {
+		backoff = defaultChangesBackoff
+	}
+
+	for attempt := 0; ; {
+		lastSeq, delivered, err := db.streamChanges(ctx, opts, since, handler)
+		if lastSeq != "" {
+			since = lastSeq
+		}
+		if delivered {
+			// The stream delivered at least one change before dropping,
+			// so this was a clean reconnect, not a repeated failure:
+			// don't let it inherit the prior attempt's backoff.
+			attempt = 0
+		}
+
+		switch err {
+		case nil, errFeedClosed:
+			if opts.Feed == "longpoll" {
+				return nil
+			}
+			if err == nil {
+				return nil
+			}
+		default:
+			var stop *handlerStopError
+			if errors.As(err, &stop) {
+				return stop.err
+			}
+			var ce *CloudantError
+			if errors.As(err, &ce) && !db.retry.shouldRetry(ce.StatusCode) {
+				// A non-retryable response (bad credentials, missing db,
+				// malformed filter/selector, ...) will just recur forever
+				// on reconnect, so don't loop on it.
+				return err
+			}
+			if errors.Is(err, bufio.ErrTooLong) {
+				// A change line over streamChanges' scan buffer would hit
+				// the same line again on reconnect-from-same-seq, so this
+				// is fatal too, not a transient read error.
+				return err
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		select {
+		case <-time.After(backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		attempt++
+	}
+}
+
+func defaultChangesBackoff(attempt int) time.Duration {
+	d := time.Duration(attempt+1) * 500 * time.Millisecond
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
+
+// streamChanges issues a single request against _changes and streams the
+// response until the connection closes, ctx is cancelled, or handler
+// stops it. It returns the last seq it observed so the caller can resume.
+func (db *DB) streamChanges(ctx context.Context, opts ChangesOptions, since string, handler ChangesHandler) (string, bool, error) {
+	var delivered bool
+
+	feed := opts.Feed
+	if feed == "" {
+		feed = "continuous"
+	}
+
+	q := map[string]interface{}{
+		"feed":         feed,
+		"since":        since,
+		"include_docs": opts.IncludeDocs,
+	}
+	if opts.Heartbeat > 0 {
+		q["heartbeat"] = strconv.Itoa(opts.Heartbeat)
+	}
+	if opts.Selector != nil {
+		q["filter"] = "_selector"
+	} else if opts.Filter != "" {
+		q["filter"] = opts.Filter
+	}
+
+	reqURL := fmt.Sprintf("%s/%s/_changes?%s", db.Host, db.Database, mapToQueryString(q))
+
+	var httpReq *http.Request
+	var err error
+	if opts.Selector != nil {
+		payload, marshalErr := json.Marshal(map[string]interface{}{"selector": opts.Selector})
+		if marshalErr != nil {
+			return since, false, marshalErr
+		}
+		httpReq, err = http.NewRequestWithContext(ctx, http.MethodPost, reqURL, bytes.NewReader(payload))
+		if err == nil {
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+	} else {
+		httpReq, err = http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	}
+	if err != nil {
+		return since, false, err
+	}
+	if err := db.authenticate(httpReq); err != nil {
+		return since, false, err
+	}
+
+	resp, err := db.client().Do(httpReq)
+	if err != nil {
+		return since, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		body, readErr := io.ReadAll(resp.Body)
+		if readErr != nil {
+			return since, false, readErr
+		}
+		return since, false, newCloudantError(resp.StatusCode, body)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue // heartbeat newline
+		}
+
+		var change Change
+		if unmarshalErr := json.Unmarshal(line, &change); unmarshalErr != nil {
+			// last_seq summary line and other non-change lines, ignore.
+			continue
+		}
+		if change.ID == "" {
+			continue
+		}
+
+		if handlerErr := handler(change); handlerErr != nil {
+			return change.Seq, true, &handlerStopError{handlerErr}
+		}
+		since = change.Seq
+		delivered = true
+	}
+
+	if scanErr := scanner.Err(); scanErr != nil {
+		return since, delivered, scanErr
+	}
+
+	return since, delivered, errFeedClosed
+}
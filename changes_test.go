@@ -0,0 +1,94 @@
+package cloudant
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestChangesResumesFromLastSeqAndResetsBackoff simulates a continuous feed
+// that drops the connection after every change it delivers. It asserts
+// each reconnect resumes from the previous change's seq (never replays
+// doc1) and that attempt stays at 0 throughout, since every reconnect
+// delivered a change (see the chunk0-1 backoff-reset fix).
+func TestChangesResumesFromLastSeqAndResetsBackoff(t *testing.T) {
+	var gotSince []string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSince = append(gotSince, r.URL.Query().Get("since"))
+		n := strconv.Itoa(len(gotSince))
+		w.Write([]byte(`{"seq":"` + n + `","id":"doc` + n + `"}` + "\n"))
+	}))
+	defer srv.Close()
+
+	db := Setup("", "", "db", srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	var attempts []int
+	var delivered []string
+	err := db.Changes(ctx, ChangesOptions{
+		Backoff: func(attempt int) time.Duration {
+			attempts = append(attempts, attempt)
+			return time.Millisecond
+		},
+	}, func(c Change) error {
+		delivered = append(delivered, c.ID)
+		if len(delivered) >= 3 {
+			return errStop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, errStop) {
+		t.Fatalf("expected errStop, got %v", err)
+	}
+	if len(gotSince) != 3 || gotSince[0] != "" || gotSince[1] != "1" || gotSince[2] != "2" {
+		t.Fatalf("expected resume from each prior seq, got %v", gotSince)
+	}
+	for _, a := range attempts {
+		if a != 0 {
+			t.Fatalf("expected every reconnect to see attempt=0 (backoff reset), got %v", attempts)
+		}
+	}
+}
+
+// TestChangesReturnsNonRetryableErrorInsteadOfLooping covers the review
+// fix that makes a permanent 4xx failure (bad credentials, missing db,
+// ...) return immediately instead of reconnecting until ctx is cancelled.
+func TestChangesReturnsNonRetryableErrorInsteadOfLooping(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"not_found","reason":"Database does not exist."}`))
+	}))
+	defer srv.Close()
+
+	db := Setup("", "", "missingdb", srv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := db.Changes(ctx, ChangesOptions{}, func(c Change) error { return nil })
+	elapsed := time.Since(start)
+
+	var ce *CloudantError
+	if !errors.As(err, &ce) || ce.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected a 404 CloudantError, got %v", err)
+	}
+	if hits != 1 {
+		t.Fatalf("expected exactly 1 request (no retry loop), got %d", hits)
+	}
+	if elapsed >= 2*time.Second {
+		t.Fatalf("Changes took %v, expected it to return well before the ctx deadline", elapsed)
+	}
+}
+
+var errStop = errors.New("test: stop after N changes")
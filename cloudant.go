@@ -2,12 +2,10 @@ package cloudant
 
 import (
 	"encoding/json"
-	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"strings"
-
-	"github.com/parnurzeal/gorequest"
 )
 
 // The set of constants defined here are for the various parameters
@@ -25,15 +23,58 @@ type DB struct {
 	Password string
 	Database string
 	Host     string
+
+	// httpClient, authenticator, and retry are set by SetupWithOptions;
+	// Setup leaves them nil and do() falls back to basic auth with no
+	// retries, same as the old gorequest-based behavior.
+	httpClient    *http.Client
+	authenticator Authenticator
+	retry         RetryPolicy
 }
 
 // Query defines the parameters needed to make a request against cloudant query
 type Query struct {
-	Selector interface{}
-	Fields   []string
-	Sort     []map[string]string
-	Limit    int
-	Skip     int
+	Selector interface{}         `json:"selector"`
+	Fields   []string            `json:"fields,omitempty"`
+	Sort     []map[string]string `json:"sort,omitempty"`
+	Limit    int                 `json:"limit,omitempty"`
+	Skip     int                 `json:"skip,omitempty"`
+	UseIndex string              `json:"use_index,omitempty"`
+}
+
+// CloudantError is returned whenever a Cloudant/CouchDB request fails
+// with a non-2xx status. It exposes the pieces callers need to branch on
+// (StatusCode, ErrorID, Reason) without string-matching the response
+// body, e.g. `errors.As(err, &ce); if ce.StatusCode == 409 { ... }`.
+type CloudantError struct {
+	StatusCode int
+	// ErrorID is CouchDB's short error id, e.g. "conflict" or "not_found".
+	ErrorID string
+	// Reason is CouchDB's human-readable explanation of ErrorID.
+	Reason string
+	// Body is the raw response body, for callers that need more detail.
+	Body []byte
+}
+
+func (e *CloudantError) Error() string {
+	return fmt.Sprintf("cloudant: %d %s: %s", e.StatusCode, e.ErrorID, e.Reason)
+}
+
+// newCloudantError builds a CloudantError from a non-2xx response body.
+// Body always holds the raw response; ErrorID and Reason are left empty
+// when it isn't the usual {error, reason} shape.
+func newCloudantError(statusCode int, body []byte) *CloudantError {
+	var v struct {
+		Error  string `json:"error"`
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(body, &v)
+	return &CloudantError{
+		StatusCode: statusCode,
+		ErrorID:    v.Error,
+		Reason:     v.Reason,
+		Body:       body,
+	}
 }
 
 // Setup inits all the params needed to make further requests to the cloudant API
@@ -46,27 +87,16 @@ func Setup(username, password, database, host string) *DB {
 	}
 }
 
-func (db *DB) newRequest() *gorequest.SuperAgent {
-	return gorequest.New().SetBasicAuth(db.Username, db.Password)
-}
-
 // Insert inserts a doccument and returns the rev of the doccument created
 func (db *DB) Insert(doc interface{}) (string, error) {
 	url := fmt.Sprintf("%s/%s", db.Host, db.Database)
-	req := db.newRequest()
-	resp, body, errs := req.Post(url).SendStruct(doc).EndBytes()
-	if errs != nil {
-		return "", errs[0]
+	status, body, err := db.do(http.MethodPost, url, doc)
+	if err != nil {
+		return "", err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return "", errs[0]
-		}
-
-		return "", errors.New(string(body))
+	if status/100 != 2 {
+		return "", newCloudantError(status, body)
 	}
 
 	type respJSON struct {
@@ -74,8 +104,7 @@ func (db *DB) Insert(doc interface{}) (string, error) {
 	}
 
 	var respBody respJSON
-	err := json.Unmarshal(body, &respBody)
-	if err != nil {
+	if err := json.Unmarshal(body, &respBody); err != nil {
 		return "", err
 	}
 
@@ -85,42 +114,53 @@ func (db *DB) Insert(doc interface{}) (string, error) {
 // GetByID gets a single doccument by it's _id
 func (db *DB) GetByID(id string, params map[string]interface{}) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s/%s?%s", db.Host, db.Database, id, mapToQueryString(params))
-	req := db.newRequest()
-	resp, body, errs := req.Get(url).EndBytes()
-	if errs != nil {
-		return nil, errs[0]
+	status, body, err := db.do(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(string(body))
+	if status/100 != 2 {
+		return nil, newCloudantError(status, body)
 	}
 
 	return body, nil
 }
 
+// Get fetches a single document by its _id and decodes it into T,
+// returning the document's _rev alongside it. Use errors.As(err, &ce) to
+// branch on failure, e.g. a 409 conflict or 404 not found.
+func Get[T any](db *DB, id string, params map[string]interface{}) (T, string, error) {
+	var doc T
+
+	body, err := db.GetByID(id, params)
+	if err != nil {
+		return doc, "", err
+	}
+
+	var rev struct {
+		Rev string `json:"_rev"`
+	}
+	if err := json.Unmarshal(body, &rev); err != nil {
+		return doc, "", err
+	}
+
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return doc, "", err
+	}
+
+	return doc, rev.Rev, nil
+}
+
 // Update will update a single doccument with the new doccument and returns the rev of the doccument updated
 func (db *DB) Update(id string, doc interface{}) (string, error) {
 	url := fmt.Sprintf("%s/%s/%s", db.Host, db.Database, id)
-	req := db.newRequest()
-	resp, body, errs := req.Put(url).SendStruct(doc).EndBytes()
-	if errs != nil {
-		return "", errs[0]
+	status, body, err := db.do(http.MethodPut, url, doc)
+	if err != nil {
+		return "", err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return "", err
-		}
-
-		return "", errors.New(string(body))
+	if status/100 != 2 {
+		return "", newCloudantError(status, body)
 	}
 
 	type respJSON struct {
@@ -128,9 +168,8 @@ func (db *DB) Update(id string, doc interface{}) (string, error) {
 	}
 
 	var respBody respJSON
-	err := json.Unmarshal(body, &respBody)
-	if err != nil {
-		return "", errs[0]
+	if err := json.Unmarshal(body, &respBody); err != nil {
+		return "", err
 	}
 
 	return respBody.Rev, nil
@@ -139,20 +178,13 @@ func (db *DB) Update(id string, doc interface{}) (string, error) {
 // Delete will delete a doccument
 func (db *DB) Delete(id, rev string) error {
 	url := fmt.Sprintf("%s/%s/%s?rev=%s", db.Host, db.Database, id, rev)
-	req := db.newRequest()
-	resp, body, errs := req.Delete(url).EndBytes()
-	if errs != nil {
-		return errs[0]
+	status, body, err := db.do(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return err
-		}
-
-		return errors.New(string(body))
+	if status/100 != 2 {
+		return newCloudantError(status, body)
 	}
 
 	return nil
@@ -161,44 +193,47 @@ func (db *DB) Delete(id, rev string) error {
 // Query performs a cloudant query call
 func (db *DB) Query(params interface{}) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s/_find", db.Host, db.Database)
-	req := db.newRequest()
+	status, body, err := db.do(http.MethodPost, url, params)
+	if err != nil {
+		return nil, err
+	}
 
-	resp, body, errs := req.Post(url).SendStruct(params).EndBytes()
-	if errs != nil {
-		return nil, errs[0]
+	if status/100 != 2 {
+		return nil, newCloudantError(status, body)
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return nil, err
-		}
+	return body, nil
+}
 
-		return nil, errors.New(string(body))
+// QueryInto performs a cloudant query call and unmarshals the `docs` field
+// of the response into results, which must be a pointer to a slice of T.
+func QueryInto[T any](db *DB, params interface{}, results *[]T) error {
+	body, err := db.Query(params)
+	if err != nil {
+		return err
 	}
 
-	return body, nil
+	var parsed struct {
+		Docs []T `json:"docs"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return err
+	}
+
+	*results = parsed.Docs
+	return nil
 }
 
 // View gets data from a view
 func (db *DB) View(ddoc string, iName string, q map[string]interface{}) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s/_design/%s/_view/%s?%s", db.Host, db.Database, ddoc, iName, mapToQueryString(q))
-	req := db.newRequest()
-
-	resp, body, errs := req.Get(url).EndBytes()
-	if errs != nil {
-		return nil, errs[0]
+	status, body, err := db.do(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(string(body))
+	if status/100 != 2 {
+		return nil, newCloudantError(status, body)
 	}
 
 	return body, nil
@@ -207,21 +242,13 @@ func (db *DB) View(ddoc string, iName string, q map[string]interface{}) ([]byte,
 // Search performs a lucene search
 func (db *DB) Search(ddoc string, iName string, q map[string]interface{}) ([]byte, error) {
 	url := fmt.Sprintf("%s/%s/_design/%s/_search/%s?%s", db.Host, db.Database, ddoc, iName, mapToQueryString(q))
-	req := db.newRequest()
-
-	resp, body, errs := req.Get(url).EndBytes()
-	if errs != nil {
-		return nil, errs[0]
+	status, body, err := db.do(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if resp.StatusCode/100 != 2 {
-		var v map[string]string
-		err := json.Unmarshal(body, &v)
-		if err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(string(body))
+	if status/100 != 2 {
+		return nil, newCloudantError(status, body)
 	}
 
 	return body, nil
@@ -236,7 +263,7 @@ func mapToQueryString(m map[string]interface{}) string {
 				continue
 			}
 			q = q + fmt.Sprintf("%s=%s&", k, url.QueryEscape(v))
-		case int32, int64:
+		case int, int32, int64:
 			q = q + fmt.Sprintf("%s=%d&", k, v)
 		case bool:
 			q = q + fmt.Sprintf("%s=%t&", k, v)
@@ -0,0 +1,85 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testDoc struct {
+	ID   string `json:"_id,omitempty"`
+	Rev  string `json:"_rev,omitempty"`
+	Name string `json:"name"`
+}
+
+func TestGetDecodesDocAndRev(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/testdb/doc1" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(testDoc{ID: "doc1", Rev: "1-abc", Name: "alice"})
+	}))
+	defer srv.Close()
+
+	db := SetupWithOptions(Config{Database: "testdb", Host: srv.URL})
+
+	doc, rev, err := Get[testDoc](db, "doc1", nil)
+	if err != nil {
+		t.Fatalf("Get returned error: %v", err)
+	}
+	if rev != "1-abc" {
+		t.Fatalf("expected rev 1-abc, got %q", rev)
+	}
+	if doc.Name != "alice" {
+		t.Fatalf("expected name alice, got %q", doc.Name)
+	}
+}
+
+func TestGetReturnsCloudantErrorOnNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "not_found", "reason": "missing"})
+	}))
+	defer srv.Close()
+
+	db := SetupWithOptions(Config{Database: "testdb", Host: srv.URL})
+
+	_, _, err := Get[testDoc](db, "missing", nil)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	var ce *CloudantError
+	if !errors.As(err, &ce) {
+		t.Fatalf("expected *CloudantError, got %T", err)
+	}
+	if ce.StatusCode != http.StatusNotFound || ce.ErrorID != "not_found" {
+		t.Fatalf("unexpected CloudantError: %+v", ce)
+	}
+}
+
+func TestQueryIntoUnmarshalsDocs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/testdb/_find" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"docs": []testDoc{{ID: "a", Name: "alice"}, {ID: "b", Name: "bob"}},
+		})
+	}))
+	defer srv.Close()
+
+	db := SetupWithOptions(Config{Database: "testdb", Host: srv.URL})
+
+	var results []testDoc
+	if err := QueryInto(db, NewQuery().Where("name", Gt("")).Build(), &results); err != nil {
+		t.Fatalf("QueryInto returned error: %v", err)
+	}
+	if len(results) != 2 || results[0].Name != "alice" || results[1].Name != "bob" {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+}
@@ -0,0 +1,270 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// IndexDef describes an index to create with CreateIndex, wrapping
+// POST /_index.
+type IndexDef struct {
+	// Fields are the document fields to index, in sort order.
+	Fields []string
+	// Name, if set, names the index; Cloudant generates one otherwise.
+	Name string
+	// DDoc, if set, groups this index under an existing design document.
+	DDoc string
+	// Type is the index type: "json" (the default) or "text".
+	Type string
+}
+
+type indexDefDoc struct {
+	Index struct {
+		Fields []map[string]string `json:"fields"`
+	} `json:"index"`
+	Name string `json:"name,omitempty"`
+	DDoc string `json:"ddoc,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// IndexInfo is a single entry returned by ListIndexes.
+type IndexInfo struct {
+	DDoc string `json:"ddoc"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Def  struct {
+		Fields []map[string]string `json:"fields"`
+	} `json:"def"`
+}
+
+// CreateIndex creates a Cloudant Query index via POST /_index and
+// returns its generated id and name.
+func (db *DB) CreateIndex(def IndexDef) (id string, name string, err error) {
+	url := fmt.Sprintf("%s/%s/_index", db.Host, db.Database)
+
+	doc := indexDefDoc{Name: def.Name, DDoc: def.DDoc, Type: def.Type}
+	for _, field := range def.Fields {
+		doc.Index.Fields = append(doc.Index.Fields, map[string]string{field: Asc})
+	}
+
+	status, body, err := db.do(http.MethodPost, url, doc)
+	if err != nil {
+		return "", "", err
+	}
+
+	if status/100 != 2 {
+		return "", "", newCloudantError(status, body)
+	}
+
+	var respBody struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &respBody); err != nil {
+		return "", "", err
+	}
+
+	return respBody.ID, respBody.Name, nil
+}
+
+// ListIndexes lists the indexes defined on the database via GET /_index.
+func (db *DB) ListIndexes() ([]IndexInfo, error) {
+	url := fmt.Sprintf("%s/%s/_index", db.Host, db.Database)
+
+	status, body, err := db.do(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if status/100 != 2 {
+		return nil, newCloudantError(status, body)
+	}
+
+	var parsed struct {
+		Indexes []IndexInfo `json:"indexes"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, err
+	}
+
+	return parsed.Indexes, nil
+}
+
+// DeleteIndex deletes the index named name under design document ddoc
+// via DELETE /_index/{ddoc}/{typ}/{name}. typ must match the index's
+// Type as passed to CreateIndex ("json" or "text"); pass "" for "json".
+//
+// This takes typ as an explicit parameter rather than defaulting it away
+// entirely: the underlying endpoint is scoped by index type, and a text
+// index can only be deleted by passing "text" here, so the signature
+// exposes that instead of hiding a case CreateIndex callers can hit.
+func (db *DB) DeleteIndex(ddoc, typ, name string) error {
+	if typ == "" {
+		typ = "json"
+	}
+	url := fmt.Sprintf("%s/%s/_index/%s/%s/%s", db.Host, db.Database, ddoc, typ, name)
+
+	status, body, err := db.do(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	if status/100 != 2 {
+		return newCloudantError(status, body)
+	}
+
+	return nil
+}
+
+// Cond is a single Cloudant Query operator value, e.g. Gt(21) builds
+// {"$gt": 21}.
+type Cond map[string]interface{}
+
+// Gt builds a "$gt" condition.
+func Gt(v interface{}) Cond { return Cond{"$gt": v} }
+
+// Gte builds a "$gte" condition.
+func Gte(v interface{}) Cond { return Cond{"$gte": v} }
+
+// Lt builds a "$lt" condition.
+func Lt(v interface{}) Cond { return Cond{"$lt": v} }
+
+// Lte builds a "$lte" condition.
+func Lte(v interface{}) Cond { return Cond{"$lte": v} }
+
+// Eq builds an "$eq" condition.
+func Eq(v interface{}) Cond { return Cond{"$eq": v} }
+
+// Ne builds a "$ne" condition.
+func Ne(v interface{}) Cond { return Cond{"$ne": v} }
+
+// In builds an "$in" condition.
+func In(values ...interface{}) Cond { return Cond{"$in": values} }
+
+// Regex builds a "$regex" condition.
+func Regex(pattern string) Cond { return Cond{"$regex": pattern} }
+
+// Exists builds an "$exists" condition.
+func Exists(b bool) Cond { return Cond{"$exists": b} }
+
+// QueryBuilder builds the JSON body that POST /_find accepts, so callers
+// don't have to hand-assemble map[string]interface{} trees against the
+// raw $gt/$lt/$eq constants. Pass the result directly to DB.Query.
+type QueryBuilder struct {
+	and      []map[string]interface{}
+	or       []map[string]interface{}
+	fields   []string
+	sort     []map[string]string
+	limit    int
+	skip     int
+	useIndex string
+}
+
+// NewQuery starts a new QueryBuilder.
+func NewQuery() *QueryBuilder {
+	return &QueryBuilder{}
+}
+
+// Where adds the first selector condition. Equivalent to And.
+func (b *QueryBuilder) Where(field string, cond Cond) *QueryBuilder {
+	return b.And(field, cond)
+}
+
+// And adds a condition that must also match (combined with "$and" once
+// an Or condition is also present, otherwise as a plain top-level field).
+func (b *QueryBuilder) And(field string, cond Cond) *QueryBuilder {
+	b.and = append(b.and, map[string]interface{}{field: cond})
+	return b
+}
+
+// Or adds a condition to an "$or" group alongside any other Or calls.
+func (b *QueryBuilder) Or(field string, cond Cond) *QueryBuilder {
+	b.or = append(b.or, map[string]interface{}{field: cond})
+	return b
+}
+
+// Fields limits the returned document fields.
+func (b *QueryBuilder) Fields(fields ...string) *QueryBuilder {
+	b.fields = fields
+	return b
+}
+
+// Sort adds a sort field; dir is Asc or "desc".
+func (b *QueryBuilder) Sort(field, dir string) *QueryBuilder {
+	b.sort = append(b.sort, map[string]string{field: dir})
+	return b
+}
+
+// Limit caps the number of documents returned.
+func (b *QueryBuilder) Limit(n int) *QueryBuilder {
+	b.limit = n
+	return b
+}
+
+// Skip skips the first n matching documents.
+func (b *QueryBuilder) Skip(n int) *QueryBuilder {
+	b.skip = n
+	return b
+}
+
+// UseIndex pins the query to a specific index by name.
+func (b *QueryBuilder) UseIndex(name string) *QueryBuilder {
+	b.useIndex = name
+	return b
+}
+
+func (b *QueryBuilder) selector() interface{} {
+	switch {
+	case len(b.or) == 0:
+		merged := map[string]interface{}{}
+		for _, clause := range b.and {
+			for field, cond := range clause {
+				existing, ok := merged[field].(Cond)
+				newCond, isCond := cond.(Cond)
+				if !ok || !isCond {
+					// First condition on this field, or a non-operator
+					// value (not expected from Gt/Eq/etc, but don't
+					// silently drop it): last one wins.
+					merged[field] = cond
+					continue
+				}
+
+				combined := make(Cond, len(existing)+len(newCond))
+				for op, v := range existing {
+					combined[op] = v
+				}
+				for op, v := range newCond {
+					combined[op] = v
+				}
+				merged[field] = combined
+			}
+		}
+		return merged
+	case len(b.and) == 0:
+		return map[string]interface{}{"$or": b.or}
+	default:
+		clauses := append([]map[string]interface{}{}, b.and...)
+		clauses = append(clauses, map[string]interface{}{"$or": b.or})
+		return map[string]interface{}{"$and": clauses}
+	}
+}
+
+// Build assembles the QueryBuilder's calls into a *Query ready to pass
+// to DB.Query.
+func (b *QueryBuilder) Build() *Query {
+	return &Query{
+		Selector: b.selector(),
+		Fields:   b.fields,
+		Sort:     b.sort,
+		Limit:    b.limit,
+		Skip:     b.skip,
+		UseIndex: b.useIndex,
+	}
+}
+
+// MarshalJSON implements json.Marshaler so a *QueryBuilder can be passed
+// directly to DB.Query without an explicit Build() call.
+func (b *QueryBuilder) MarshalJSON() ([]byte, error) {
+	return json.Marshal(b.Build())
+}
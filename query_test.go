@@ -0,0 +1,103 @@
+package cloudant
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestQueryBuilderMergesFlatAndClauses(t *testing.T) {
+	q := NewQuery().Where("age", Gt(21)).And("country", Eq("CA")).Build()
+
+	var parsed map[string]interface{}
+	mustUnmarshal(t, q, &parsed)
+
+	want := map[string]interface{}{
+		"age":     map[string]interface{}{"$gt": float64(21)},
+		"country": map[string]interface{}{"$eq": "CA"},
+	}
+	if !reflect.DeepEqual(parsed["selector"], want) {
+		t.Fatalf("unexpected selector: %#v", parsed["selector"])
+	}
+}
+
+func TestQueryBuilderMergesRepeatedFieldIntoOneOperatorObject(t *testing.T) {
+	q := NewQuery().Where("age", Gt(21)).And("age", Lt(65)).Build()
+
+	var parsed map[string]interface{}
+	mustUnmarshal(t, q, &parsed)
+
+	want := map[string]interface{}{
+		"age": map[string]interface{}{"$gt": float64(21), "$lt": float64(65)},
+	}
+	if !reflect.DeepEqual(parsed["selector"], want) {
+		t.Fatalf("unexpected selector: %#v", parsed["selector"])
+	}
+}
+
+func TestQueryBuilderOr(t *testing.T) {
+	q := NewQuery().Or("status", Eq("a")).Or("status", Eq("b")).Build()
+
+	var parsed map[string]interface{}
+	mustUnmarshal(t, q, &parsed)
+
+	sel, ok := parsed["selector"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected selector to be a map, got %#v", parsed["selector"])
+	}
+	orClauses, ok := sel["$or"].([]interface{})
+	if !ok || len(orClauses) != 2 {
+		t.Fatalf("expected 2 $or clauses, got %#v", sel["$or"])
+	}
+}
+
+func TestQueryBuilderAndOrCombinesWithAnd(t *testing.T) {
+	q := NewQuery().Where("active", Eq(true)).Or("status", Eq("a")).Or("status", Eq("b")).Build()
+
+	var parsed map[string]interface{}
+	mustUnmarshal(t, q, &parsed)
+
+	sel, ok := parsed["selector"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("expected selector to be a map, got %#v", parsed["selector"])
+	}
+	andClauses, ok := sel["$and"].([]interface{})
+	if !ok || len(andClauses) != 2 {
+		t.Fatalf("expected 2 $and clauses (active + $or group), got %#v", sel["$and"])
+	}
+}
+
+func TestQueryBuilderMarshalJSONMatchesBuild(t *testing.T) {
+	b := NewQuery().Where("age", Gt(21)).Sort("age", Asc).Fields("name", "age").Limit(10).Skip(5).UseIndex("by-age")
+
+	viaMarshal, err := json.Marshal(b)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	viaBuild, err := json.Marshal(b.Build())
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	if string(viaMarshal) != string(viaBuild) {
+		t.Fatalf("MarshalJSON diverged from Build(): %s vs %s", viaMarshal, viaBuild)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(viaBuild, &parsed); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+	if parsed["limit"] != float64(10) || parsed["skip"] != float64(5) || parsed["use_index"] != "by-age" {
+		t.Fatalf("unexpected query body: %#v", parsed)
+	}
+}
+
+func mustUnmarshal(t *testing.T, v interface{}, out interface{}) {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		t.Fatalf("unmarshal error: %v", err)
+	}
+}
@@ -0,0 +1,125 @@
+package cloudant
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSyncTarget is a minimal in-memory CouchDB stand-in with real
+// optimistic-concurrency semantics (a PUT with a stale/missing _rev gets a
+// 409), enough to exercise Sync's checkpoint read-modify-write cycle.
+type fakeSyncTarget struct {
+	mu    sync.Mutex
+	docs  map[string]map[string]interface{}
+	revOf map[string]int
+}
+
+func newFakeSyncTarget() *fakeSyncTarget {
+	return &fakeSyncTarget{docs: map[string]map[string]interface{}{}, revOf: map[string]int{}}
+}
+
+func (f *fakeSyncTarget) server(name string) *httptest.Server {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/"+name+"/_bulk_docs", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Docs []map[string]interface{} `json:"docs"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		results := make([]map[string]interface{}, 0, len(body.Docs))
+		for _, d := range body.Docs {
+			results = append(results, map[string]interface{}{"id": d["_id"], "rev": "1-bulk", "ok": true})
+		}
+		json.NewEncoder(w).Encode(results)
+	})
+
+	mux.HandleFunc("/"+name+"/", func(w http.ResponseWriter, r *http.Request) {
+		id := r.URL.Path[len("/"+name+"/"):]
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+
+		switch r.Method {
+		case http.MethodGet:
+			doc, ok := f.docs[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(map[string]string{"error": "not_found", "reason": "missing"})
+				return
+			}
+			json.NewEncoder(w).Encode(doc)
+
+		case http.MethodPut:
+			var incoming map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&incoming)
+
+			currentRev := f.revOf[id]
+			incomingRev, _ := incoming["_rev"].(string)
+			if currentRev > 0 && incomingRev != strconv.Itoa(currentRev) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]string{"error": "conflict", "reason": "Document update conflict."})
+				return
+			}
+
+			newRev := currentRev + 1
+			incoming["_rev"] = strconv.Itoa(newRev)
+			f.docs[id] = incoming
+			f.revOf[id] = newRev
+
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]string{"rev": strconv.Itoa(newRev)})
+
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	return httptest.NewServer(mux)
+}
+
+// TestSyncAdvancesCheckpointAcrossMultipleFlushes covers the chunk0-4 fix:
+// with BatchSize small enough to force several checkpoint writes in one
+// Sync call, each write must carry forward the _rev from the previous
+// one, or the target rejects it with a 409 (the bug this test guards).
+func TestSyncAdvancesCheckpointAcrossMultipleFlushes(t *testing.T) {
+	srcSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for i := 1; i <= 3; i++ {
+			line, _ := json.Marshal(map[string]interface{}{
+				"seq": strconv.Itoa(i),
+				"id":  "doc" + strconv.Itoa(i),
+				"doc": map[string]interface{}{"_id": "doc" + strconv.Itoa(i), "n": i},
+			})
+			w.Write(line)
+			w.Write([]byte("\n"))
+		}
+	}))
+	defer srcSrv.Close()
+
+	target := newFakeSyncTarget()
+	tgtSrv := target.server("target")
+	defer tgtSrv.Close()
+
+	srcDB := Setup("", "", "source", srcSrv.URL)
+	tgtDB := Setup("", "", "target", tgtSrv.URL)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := Sync(ctx, srcDB, tgtDB, SyncOptions{BatchSize: 1}); err != nil {
+		t.Fatalf("Sync returned error: %v", err)
+	}
+
+	target.mu.Lock()
+	rev := target.revOf["_local/sync-checkpoint"]
+	target.mu.Unlock()
+
+	if rev != 4 { // 1 write per doc (3) + the final flush of the trailing seq
+		t.Fatalf("expected checkpoint to advance through 4 writes, got rev=%d", rev)
+	}
+}
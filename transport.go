@@ -0,0 +1,343 @@
+package cloudant
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Authenticator applies credentials to an outgoing request. It lets DB
+// support schemes beyond HTTP basic auth (cookie sessions, IAM bearer
+// tokens) without changing any of the request-building code.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// BasicAuthenticator is the default Authenticator: HTTP basic auth, the
+// same thing newRequest used to set via gorequest.SetBasicAuth.
+type BasicAuthenticator struct {
+	Username string
+	Password string
+}
+
+// Authenticate implements Authenticator.
+func (a BasicAuthenticator) Authenticate(req *http.Request) error {
+	req.SetBasicAuth(a.Username, a.Password)
+	return nil
+}
+
+// CookieAuthenticator authenticates via CouchDB's /_session endpoint,
+// exchanging a username/password for an AuthSession cookie and
+// re-establishing it once the cookie expires.
+type CookieAuthenticator struct {
+	Host     string
+	Username string
+	Password string
+	Client   *http.Client
+
+	mu     sync.Mutex
+	cookie *http.Cookie
+}
+
+// Authenticate implements Authenticator.
+func (a *CookieAuthenticator) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cookie == nil || cookieExpired(a.cookie) {
+		if err := a.refresh(); err != nil {
+			return err
+		}
+	}
+
+	req.AddCookie(a.cookie)
+	return nil
+}
+
+func (a *CookieAuthenticator) refresh() error {
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	payload, err := json.Marshal(map[string]string{"name": a.Username, "password": a.Password})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/_session", a.Host), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("cloudant: _session auth failed with status %d", resp.StatusCode)
+	}
+
+	for _, c := range resp.Cookies() {
+		if c.Name == "AuthSession" {
+			a.cookie = c
+			return nil
+		}
+	}
+
+	return fmt.Errorf("cloudant: _session response did not set an AuthSession cookie")
+}
+
+func cookieExpired(c *http.Cookie) bool {
+	return !c.Expires.IsZero() && time.Now().After(c.Expires)
+}
+
+// IAMAuthenticator authenticates with IBM Cloud IAM, exchanging an API
+// key for a bearer token and refreshing it before it expires.
+type IAMAuthenticator struct {
+	APIKey   string
+	TokenURL string // defaults to IBM Cloud's public IAM token endpoint
+	Client   *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+const defaultIAMTokenURL = "https://iam.cloud.ibm.com/identity/token"
+
+// Authenticate implements Authenticator.
+func (a *IAMAuthenticator) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || time.Now().After(a.expiresAt) {
+		if err := a.refresh(); err != nil {
+			return err
+		}
+	}
+
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+func (a *IAMAuthenticator) refresh() error {
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	tokenURL := a.TokenURL
+	if tokenURL == "" {
+		tokenURL = defaultIAMTokenURL
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ibm:params:oauth:grant-type:apikey"},
+		"apikey":     {a.APIKey},
+	}
+	req, err := http.NewRequest(http.MethodPost, tokenURL, bytes.NewReader([]byte(form.Encode())))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return newCloudantError(resp.StatusCode, body)
+	}
+
+	var parsed struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return err
+	}
+
+	a.token = parsed.AccessToken
+	// Refresh a minute early so a request never races token expiry.
+	a.expiresAt = time.Now().Add(time.Duration(parsed.ExpiresIn)*time.Second - time.Minute)
+	return nil
+}
+
+// RetryPolicy controls how DB retries requests that fail with a
+// transient status. The zero value disables retries.
+type RetryPolicy struct {
+	// MaxRetries is how many additional attempts to make after the
+	// first. Zero disables retries.
+	MaxRetries int
+	// BaseDelay is the delay before the first retry; it doubles on each
+	// subsequent attempt up to MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the computed backoff.
+	MaxDelay time.Duration
+}
+
+func (p RetryPolicy) shouldRetry(statusCode int) bool {
+	return statusCode == 429 || statusCode == 503
+}
+
+// delay returns how long to wait before the given retry attempt
+// (0-indexed), honoring a Retry-After header when the server sent one.
+func (p RetryPolicy) delay(attempt int, retryAfter string) time.Duration {
+	if retryAfter != "" {
+		if secs, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(secs) * time.Second
+		}
+	}
+
+	base := p.BaseDelay
+	if base <= 0 {
+		base = 250 * time.Millisecond
+	}
+	d := time.Duration(float64(base) * math.Pow(2, float64(attempt)))
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+// Config holds everything SetupWithOptions needs to build a DB, for
+// callers that need more than Setup's basic-auth default: a custom
+// *http.Client or RoundTripper for connection pooling and test
+// interception, an Authenticator for cookie or IAM auth, and a
+// RetryPolicy for 429/503 backoff.
+type Config struct {
+	Username string
+	Password string
+	Database string
+	Host     string
+
+	// HTTPClient is used for every request DB makes. Defaults to a
+	// fresh *http.Client so connections are pooled across calls.
+	HTTPClient *http.Client
+	// Transport, when set, is installed as HTTPClient's RoundTripper.
+	// Useful for injecting an httptest.Server transport in tests.
+	Transport http.RoundTripper
+	// Authenticator defaults to BasicAuthenticator{Username, Password}.
+	Authenticator Authenticator
+	// Retry defaults to the zero value (no retries).
+	Retry RetryPolicy
+}
+
+// SetupWithOptions inits a DB the way Setup does, but lets the caller
+// supply a custom HTTP client/transport, Authenticator, and RetryPolicy.
+func SetupWithOptions(cfg Config) *DB {
+	client := cfg.HTTPClient
+	if client == nil {
+		client = &http.Client{}
+	}
+	if cfg.Transport != nil {
+		client.Transport = cfg.Transport
+	}
+
+	auth := cfg.Authenticator
+	if auth == nil {
+		auth = BasicAuthenticator{Username: cfg.Username, Password: cfg.Password}
+	}
+
+	return &DB{
+		Username:      cfg.Username,
+		Password:      cfg.Password,
+		Database:      cfg.Database,
+		Host:          cfg.Host,
+		httpClient:    client,
+		authenticator: auth,
+		retry:         cfg.Retry,
+	}
+}
+
+// client returns db's configured *http.Client, falling back to the
+// package's shared streamingHTTPClient for a DB built with Setup.
+func (db *DB) client() *http.Client {
+	if db.httpClient != nil {
+		return db.httpClient
+	}
+	return streamingHTTPClient
+}
+
+// authenticate applies db's Authenticator to req, falling back to basic
+// auth for a DB built with Setup.
+func (db *DB) authenticate(req *http.Request) error {
+	if db.authenticator != nil {
+		return db.authenticator.Authenticate(req)
+	}
+	req.SetBasicAuth(db.Username, db.Password)
+	return nil
+}
+
+// do builds and executes a request against url, JSON-encoding payload as
+// the body when non-nil, applying db.authenticator, and retrying
+// according to db.retry. It returns the final response status code and
+// body; a non-2xx status is not itself an error here, callers translate
+// it with newCloudantError.
+func (db *DB) do(method, url string, payload interface{}) (int, []byte, error) {
+	var bodyBytes []byte
+	if payload != nil {
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		bodyBytes = encoded
+	}
+
+	client := db.client()
+
+	var statusCode int
+	var respBody []byte
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequest(method, url, bytes.NewReader(bodyBytes))
+		if err != nil {
+			return 0, nil, err
+		}
+		if bodyBytes != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		if err := db.authenticate(req); err != nil {
+			return 0, nil, err
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		respBody, err = io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return 0, nil, err
+		}
+		statusCode = resp.StatusCode
+
+		if attempt < db.retry.MaxRetries && db.retry.shouldRetry(statusCode) {
+			time.Sleep(db.retry.delay(attempt, resp.Header.Get("Retry-After")))
+			continue
+		}
+
+		return statusCode, respBody, nil
+	}
+}
@@ -0,0 +1,92 @@
+package cloudant
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelayHonorsRetryAfterHeader(t *testing.T) {
+	p := RetryPolicy{BaseDelay: time.Second}
+	if d := p.delay(0, "2"); d != 2*time.Second {
+		t.Fatalf("expected 2s delay from Retry-After, got %v", d)
+	}
+}
+
+func TestRetryPolicyDelayBacksOffExponentially(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	if d := p.delay(0, ""); d != 100*time.Millisecond {
+		t.Fatalf("attempt 0: expected 100ms, got %v", d)
+	}
+	if d := p.delay(1, ""); d != 200*time.Millisecond {
+		t.Fatalf("attempt 1: expected 200ms, got %v", d)
+	}
+	if d := p.delay(10, ""); d != time.Second {
+		t.Fatalf("expected delay capped at MaxDelay, got %v", d)
+	}
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	p := RetryPolicy{}
+	if !p.shouldRetry(429) || !p.shouldRetry(503) {
+		t.Fatal("expected 429 and 503 to be retryable")
+	}
+	if p.shouldRetry(500) || p.shouldRetry(200) {
+		t.Fatal("expected only 429/503 to be retryable")
+	}
+}
+
+func TestDoRetriesOn503ThenSucceeds(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"rev":"1-ok"}`))
+	}))
+	defer srv.Close()
+
+	db := SetupWithOptions(Config{
+		Database: "testdb",
+		Host:     srv.URL,
+		Retry:    RetryPolicy{MaxRetries: 3, BaseDelay: time.Millisecond},
+	})
+
+	rev, err := db.Insert(map[string]string{"foo": "bar"})
+	if err != nil {
+		t.Fatalf("Insert returned error: %v", err)
+	}
+	if rev != "1-ok" {
+		t.Fatalf("expected rev 1-ok, got %q", rev)
+	}
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestDoGivesUpAfterMaxRetries(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	db := SetupWithOptions(Config{
+		Database: "testdb",
+		Host:     srv.URL,
+		Retry:    RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond},
+	})
+
+	_, err := db.Insert(map[string]string{"foo": "bar"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if attempts != 3 { // initial attempt + 2 retries
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}